@@ -0,0 +1,292 @@
+/**
+ * Copyright (C) 2015 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *         http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmds
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/fabric8io/gofabric8/util"
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/spf13/cobra"
+	cmdutil "k8s.io/kubernetes/pkg/kubectl/cmd/util"
+	"k8s.io/kubernetes/pkg/util/homedir"
+)
+
+const (
+	cacheImagesFlag = "cache-images"
+	imageCacheDir   = ".fabric8/cache/images"
+)
+
+// defaultFabric8PlatformImages are always pre-pulled when --cache-images is
+// set, in addition to any images added via the config or `fabric8 cache add`
+var defaultFabric8PlatformImages = []string{
+	"fabric8/fabric8-console:latest",
+	"fabric8/fabric8-forge:latest",
+}
+
+// NewCmdCache manages the local cache of container images used to bring up
+// the fabric8 microservices platform without a registry
+func NewCmdCache(f *cmdutil.Factory) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manages the local cache of container images used for air-gapped installs",
+		Long:  `Manages the local cache of container images used for air-gapped installs`,
+	}
+	cmd.AddCommand(NewCmdCacheAdd(f))
+	cmd.AddCommand(NewCmdCacheList(f))
+	cmd.AddCommand(NewCmdCacheDelete(f))
+	cmd.AddCommand(NewCmdCacheLoad(f))
+	return cmd
+}
+
+// NewCmdCacheAdd adds a container image to the local cache and to the
+// persisted list of images fabric8 install --cache-images will pre-pull
+func NewCmdCacheAdd(f *cmdutil.Factory) *cobra.Command {
+	return &cobra.Command{
+		Use:   "add <image>",
+		Short: "Pulls a container image into the local cache and remembers it for future installs",
+		Long:  `Pulls a container image into the local cache and remembers it for future installs`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) != 1 {
+				util.Fatalf("Usage: fabric8 cache add <image>\n")
+			}
+			image := args[0]
+
+			o := newOutputFormatter(outputText)
+			if err := cacheImage(image, o); err != nil {
+				util.Errorf("Unable to cache image %s %v\n", image, err)
+				return
+			}
+
+			config, err := loadFabric8Config()
+			if err != nil {
+				util.Errorf("Unable to load fabric8 config %v\n", err)
+				return
+			}
+			if !containsString(config.Images, image) {
+				config.Images = append(config.Images, image)
+				if err := saveFabric8Config(config); err != nil {
+					util.Errorf("Unable to save fabric8 config %v\n", err)
+				}
+			}
+		},
+	}
+}
+
+// NewCmdCacheList lists the images recorded in ~/.fabric8/config.yaml by
+// `fabric8 cache add`; the default fabric8 platform images are not listed
+func NewCmdCacheList(f *cmdutil.Factory) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "Lists the container images added to the local cache with 'fabric8 cache add'",
+		Long:  `Lists the container images added to the local cache with 'fabric8 cache add'`,
+		Run: func(cmd *cobra.Command, args []string) {
+			config, err := loadFabric8Config()
+			if err != nil {
+				util.Errorf("Unable to load fabric8 config %v\n", err)
+				return
+			}
+			if len(config.Images) == 0 {
+				util.Infof("No images cached\n")
+				return
+			}
+			for _, image := range config.Images {
+				fmt.Println(image)
+			}
+		},
+	}
+}
+
+// NewCmdCacheDelete removes a container image's tarball from the local
+// cache and forgets it from the persisted list
+func NewCmdCacheDelete(f *cmdutil.Factory) *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <image>",
+		Short: "Deletes a container image from the local cache",
+		Long:  `Deletes a container image from the local cache`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) != 1 {
+				util.Fatalf("Usage: fabric8 cache delete <image>\n")
+			}
+			image := args[0]
+
+			path := cachedImagePath(image)
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				util.Errorf("Unable to delete cached image %s %v\n", image, err)
+				return
+			}
+
+			config, err := loadFabric8Config()
+			if err != nil {
+				util.Errorf("Unable to load fabric8 config %v\n", err)
+				return
+			}
+			config.Images = removeString(config.Images, image)
+			if err := saveFabric8Config(config); err != nil {
+				util.Errorf("Unable to save fabric8 config %v\n", err)
+				return
+			}
+			util.Successf("Deleted %s from the local cache\n", image)
+		},
+	}
+}
+
+// NewCmdCacheLoad side-loads cached image tarballs into the docker daemon
+// the caller's environment currently points at (e.g. after running
+// `eval $(minikube docker-env)`/`eval $(minishift docker-env)`), so it must
+// be run against a started VM rather than as part of `fabric8 install`
+func NewCmdCacheLoad(f *cmdutil.Factory) *cobra.Command {
+	return &cobra.Command{
+		Use:   "load [image]",
+		Short: "Loads cached container images into the docker daemon of the current environment",
+		Long: `Loads cached container images into the docker daemon of the current environment.
+
+Run this against the VM's docker daemon (e.g. after 'eval $(minikube docker-env)'
+or 'eval $(minishift docker-env)'), not against your host docker daemon.
+With no arguments it loads every image in the local cache; given an image
+it loads just that one.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			o := newOutputFormatter(outputText)
+
+			if len(args) == 1 {
+				if err := loadCachedImage(args[0], o); err != nil {
+					util.Errorf("Unable to load cached image %s %v\n", args[0], err)
+				}
+				return
+			}
+
+			if err := loadCachedImages(getCacheImages(o), o); err != nil {
+				util.Errorf("Unable to load cached images %v\n", err)
+			}
+		},
+	}
+}
+
+func getImageCacheLocation() string {
+	home := homedir.HomeDir()
+	if home == "" {
+		util.Fatalf("No user home environment variable found for OS %s", runtime.GOOS)
+	}
+	return filepath.Join(home, imageCacheDir)
+}
+
+// cachedImagePath returns the path an image's OCI tarball is stored at,
+// sanitising the image reference into a filesystem-safe name
+func cachedImagePath(image string) string {
+	replacer := strings.NewReplacer("/", "_", ":", "_", "@", "_")
+	return filepath.Join(getImageCacheLocation(), replacer.Replace(image)+".tar")
+}
+
+// cacheImages pre-pulls every image in images into the local cache,
+// continuing on individual failures so one bad image doesn't abort the rest
+func cacheImages(images []string, o *outputFormatter) error {
+	cacheDir := getImageCacheLocation()
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return err
+	}
+
+	for _, image := range images {
+		if err := cacheImage(image, o); err != nil {
+			o.warnf("Unable to cache image %s %v\n", image, err)
+		}
+	}
+	return nil
+}
+
+// cacheImage pulls a single image and saves it as a docker-loadable tarball
+// under ~/.fabric8/cache/images/, using go-containerregistry so no local
+// docker daemon is required to do the pull
+func cacheImage(image string, o *outputFormatter) error {
+	path := cachedImagePath(image)
+
+	if _, err := os.Stat(path); err == nil {
+		o.successf("%s is already cached\n", image)
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	o.infof("Pulling %s...\n", image)
+	img, err := crane.Pull(image)
+	if err != nil {
+		return fmt.Errorf("unable to pull %s: %v", image, err)
+	}
+
+	if err := crane.Save(img, image, path); err != nil {
+		return fmt.Errorf("unable to save %s to %s: %v", image, path, err)
+	}
+
+	o.successf("Cached %s -> %s\n", image, path)
+	return nil
+}
+
+// loadCachedImages side-loads every cached image tarball into the docker
+// daemon, continuing on individual failures so one bad image doesn't abort
+// the rest
+func loadCachedImages(images []string, o *outputFormatter) error {
+	for _, image := range images {
+		if err := loadCachedImage(image, o); err != nil {
+			o.warnf("Unable to load cached image %s %v\n", image, err)
+		}
+	}
+	return nil
+}
+
+// loadCachedImage side-loads a previously cached image tarball into the
+// docker daemon of whatever VM the caller's environment currently points at
+// (e.g. after `eval $(minikube docker-env)`), so minikube/minishift start
+// can use it without hitting a registry
+func loadCachedImage(image string, o *outputFormatter) error {
+	path := cachedImagePath(image)
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("no cached tarball for %s, run 'fabric8 cache add %s' first", image, image)
+	}
+
+	cmd := exec.Command(docker, "load", "-i", path)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return err
+	}
+	o.successf("Loaded %s into the docker daemon\n", image)
+	return nil
+}
+
+func containsString(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func removeString(list []string, value string) []string {
+	result := make([]string, 0, len(list))
+	for _, v := range list {
+		if v != value {
+			result = append(result, v)
+		}
+	}
+	return result
+}
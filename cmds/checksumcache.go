@@ -0,0 +1,68 @@
+/**
+ * Copyright (C) 2015 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *         http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmds
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/fabric8io/gofabric8/util"
+	"k8s.io/kubernetes/pkg/util/homedir"
+)
+
+const checksumCacheFileName = "checksum-cache.json"
+
+// checksumCache is keyed by downloaded file name, holding the SHA256 of the
+// most recent download of that file accepted via --skip-verify; it is only
+// consulted for binaries that don't publish a checksum we can verify against
+// (e.g. the pinned oc release)
+type checksumCache map[string]string
+
+func getChecksumCacheLocation() string {
+	home := homedir.HomeDir()
+	if home == "" {
+		util.Fatalf("No user home environment variable found for OS %s", runtime.GOOS)
+	}
+	return filepath.Join(home, ".fabric8", checksumCacheFileName)
+}
+
+func loadChecksumCache() (checksumCache, error) {
+	c := checksumCache{}
+	data, err := ioutil.ReadFile(getChecksumCacheLocation())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c, nil
+		}
+		return c, err
+	}
+	err = json.Unmarshal(data, &c)
+	return c, err
+}
+
+func saveChecksumCache(c checksumCache) error {
+	path := getChecksumCacheLocation()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0600)
+}
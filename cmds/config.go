@@ -0,0 +1,77 @@
+/**
+ * Copyright (C) 2015 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *         http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmds
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/fabric8io/gofabric8/util"
+	"gopkg.in/yaml.v2"
+	"k8s.io/kubernetes/pkg/util/homedir"
+)
+
+const (
+	configFileName = "config.yaml"
+)
+
+// fabric8Config is the persisted configuration for the fabric8 CLI stored
+// under ~/.fabric8/config.yaml
+type fabric8Config struct {
+	BinaryMirror   string   `yaml:"binaryMirror,omitempty"`
+	KubectlMirror  string   `yaml:"kubectlMirror,omitempty"`
+	OcMirror       string   `yaml:"ocMirror,omitempty"`
+	MinikubeMirror string   `yaml:"minikubeMirror,omitempty"`
+	Images         []string `yaml:"images,omitempty"`
+}
+
+func getFabric8ConfigLocation() string {
+	home := homedir.HomeDir()
+	if home == "" {
+		util.Fatalf("No user home environment variable found for OS %s", runtime.GOOS)
+	}
+	return filepath.Join(home, ".fabric8", configFileName)
+}
+
+// loadFabric8Config reads the persisted fabric8 config, returning a zero
+// value config if no file has been written yet
+func loadFabric8Config() (fabric8Config, error) {
+	c := fabric8Config{}
+	data, err := ioutil.ReadFile(getFabric8ConfigLocation())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c, nil
+		}
+		return c, err
+	}
+	err = yaml.Unmarshal(data, &c)
+	return c, err
+}
+
+// saveFabric8Config persists the fabric8 config to ~/.fabric8/config.yaml
+func saveFabric8Config(c fabric8Config) error {
+	path := getFabric8ConfigLocation()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(&c)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0600)
+}
@@ -0,0 +1,208 @@
+/**
+ * Copyright (C) 2015 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *         http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmds
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+const (
+	driverVirtualBox = "virtualbox"
+	driverKVM2       = "kvm2"
+	driverLibvirt    = "libvirt"
+	driverXhyve      = "xhyve"
+	driverHyperkit   = "hyperkit"
+	driverHyperV     = "hyperv"
+	driverAuto       = "auto"
+
+	// docker-machine-driver-kvm2 ships as a release asset of the minikube
+	// project itself
+	kvm2DriverOrg    = kubernetes
+	kvm2DriverRepo   = minikube
+	kvm2DriverBinary = "docker-machine-driver-kvm2"
+
+	xhyveDriverBinary    = "docker-machine-driver-xhyve"
+	hyperkitDriverBinary = "docker-machine-driver-hyperkit"
+)
+
+// detectDriver resolves which docker-machine driver to use: the explicit
+// vmDriver if given, otherwise an autodetected choice for the current
+// platform
+func detectDriver(vmDriver string) (string, error) {
+	if vmDriver != "" && vmDriver != driverAuto {
+		return vmDriver, nil
+	}
+
+	switch runtime.GOOS {
+	case "darwin":
+		return driverXhyve, nil
+	case "windows":
+		return driverHyperV, nil
+	case "linux":
+		if kvmAvailable() {
+			return driverKVM2, nil
+		}
+		return driverVirtualBox, nil
+	default:
+		return driverVirtualBox, nil
+	}
+}
+
+func kvmAvailable() bool {
+	_, err := os.Stat("/dev/kvm")
+	return err == nil
+}
+
+// downloadDriver ensures the docker-machine driver binary for vmDriver is
+// present, downloading it from its GitHub release (kvm2) or installing it via
+// Homebrew (xhyve, hyperkit) when fabric8 manages the driver itself, and
+// deferring to the OS/hypervisor for drivers that ship their own tooling
+// (VirtualBox, Hyper-V)
+func downloadDriver(vmDriver string, mirrors mirrorOptions, skipVerify bool, o *outputFormatter) (result stepResult, err error) {
+	driver, err := detectDriver(vmDriver)
+	if err != nil {
+		return result, err
+	}
+
+	switch driver {
+	case driverVirtualBox:
+		o.infof("Using the VirtualBox driver - please ensure VirtualBox is installed\n")
+		return result, nil
+
+	case driverHyperV:
+		o.infof("Using the Hyper-V driver - please ensure Hyper-V is enabled\n")
+		return result, nil
+
+	case driverXhyve:
+		return downloadBrewDriver(xhyveDriverBinary, true, o)
+
+	case driverHyperkit:
+		return downloadBrewDriver(hyperkitDriverBinary, false, o)
+
+	case driverKVM2, driverLibvirt:
+		// docker-machine-driver-kvm2 is the libvirt-backed driver minikube
+		// ships today; "libvirt" is accepted as an explicit alias for users
+		// and docs that still refer to it by the hypervisor it drives
+		return downloadDriverBinary(kvm2DriverBinary, kvm2DriverOrg, kvm2DriverRepo, mirrors, skipVerify, true, o)
+
+	default:
+		return result, fmt.Errorf("unsupported --%s %q", vmDriverFlag, driver)
+	}
+}
+
+// downloadDriverBinary downloads a docker-machine-driver-* binary from its
+// GitHub releases into ~/.fabric8/bin/, chowning it to root and setting the
+// setuid bit when the driver needs elevated privileges to create VMs
+// (kvm2, xhyve)
+func downloadDriverBinary(binaryName, githubOwner, githubRepo string, mirrors mirrorOptions, skipVerify bool, needsSetuid bool, o *outputFormatter) (result stepResult, err error) {
+	_, err = exec.LookPath(binaryName)
+	if err == nil {
+		o.successf("%s is already available on your PATH\n", binaryName)
+		return result, nil
+	}
+
+	version, err := resolveVersion("", githubOwner, githubRepo, o)
+	if err != nil {
+		return result, fmt.Errorf("Unable to get latest version for %s/%s %v", githubOwner, githubRepo, err)
+	}
+	result.version = version.String()
+
+	baseURL := resolveMirror("", mirrors.general, "https://github.com/"+githubOwner+"/"+githubRepo+"/releases/download/")
+	driverURL := fmt.Sprintf(baseURL+"v%s/%s", version, binaryName)
+	result.url = driverURL
+
+	o.infof("Downloading %s...\n", driverURL)
+
+	writeFileLocation := getFabric8BinLocation()
+	file := writeFileLocation + binaryName
+
+	dl, err := downloadFile(file, driverURL, skipVerify, o)
+	result.bytes = dl.bytes
+	result.sha256 = dl.sha256
+	if err != nil {
+		o.errorf("Unable to download file %s/%s %v", file, driverURL, err)
+		return result, err
+	}
+
+	if needsSetuid {
+		if err := chownRootAndSetuid(file); err != nil {
+			return result, err
+		}
+	}
+
+	o.successf("Downloaded %s\n", binaryName)
+	return result, nil
+}
+
+// downloadBrewDriver installs a docker-machine driver via Homebrew, as
+// xhyve and hyperkit are only published that way on macOS
+func downloadBrewDriver(formula string, needsSetuid bool, o *outputFormatter) (result stepResult, err error) {
+	o.infof("fabric8 will install the %s driver via brew\n", formula)
+	info, err := exec.Command("brew", "info", formula).Output()
+
+	if err != nil || strings.Contains(string(info), "Not installed") {
+		e := exec.Command("brew", "install", formula)
+		e.Stdout = os.Stdout
+		e.Stderr = os.Stderr
+		err = e.Run()
+		if err != nil {
+			return result, err
+		}
+
+		if needsSetuid {
+			out, err := exec.Command("brew", "--prefix").Output()
+			if err != nil {
+				return result, err
+			}
+			brewPrefix := strings.TrimSpace(string(out))
+			file := brewPrefix + "/opt/" + formula + "/bin/" + formula
+			if err := chownRootAndSetuid(file); err != nil {
+				return result, err
+			}
+		}
+
+		o.successf("%s driver installed\n", formula)
+	} else {
+		o.successf("%s driver already installed\n", formula)
+	}
+
+	return result, nil
+}
+
+// chownRootAndSetuid gives root ownership and the setuid bit to a driver
+// binary that needs to create VMs with elevated privileges
+func chownRootAndSetuid(file string) error {
+	group := "wheel"
+	if runtime.GOOS == "linux" {
+		group = "root"
+	}
+
+	e := exec.Command("sudo", "chown", "root:"+group, file)
+	e.Stdout = os.Stdout
+	e.Stderr = os.Stderr
+	if err := e.Run(); err != nil {
+		return err
+	}
+
+	e = exec.Command("sudo", "chmod", "u+s", file)
+	e.Stdout = os.Stdout
+	e.Stderr = os.Stderr
+	return e.Run()
+}
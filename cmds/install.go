@@ -18,7 +18,6 @@ package cmds
 import (
 	"archive/zip"
 	"compress/gzip"
-	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -52,6 +51,7 @@ const (
 	oc                       = "oc"
 	binLocation              = ".fabric8/bin/"
 	kubeDownloadURL          = "https://storage.googleapis.com/"
+	ocDownloadURL            = "https://github.com/openshift/origin/releases/download/"
 	ocTools                  = "openshift-origin-client-tools"
 )
 
@@ -67,6 +67,68 @@ type downloadProperties struct {
 	extraPath      string
 	downloadURL    string
 	isMiniShift    bool
+	pinnedVersion  string
+	skipVerify     bool
+}
+
+// versionOptions holds the pinned versions to install rather than resolving
+// latest from GitHub
+type versionOptions struct {
+	kubernetes string
+	kubectl    string
+	minishift  string
+	oc         string
+}
+
+// mirrorOptions holds the resolved binary mirror base URLs to use in place
+// of the hardcoded upstream download locations, so users behind corporate
+// firewalls or in air-gapped environments can point at an internal
+// artifact cache
+type mirrorOptions struct {
+	general  string
+	kubectl  string
+	oc       string
+	minikube string
+}
+
+const (
+	binaryMirrorFlag   = "binary-mirror"
+	kubectlMirrorFlag  = "kubectl-mirror"
+	ocMirrorFlag       = "oc-mirror"
+	minikubeMirrorFlag = "minikube-mirror"
+
+	binaryMirrorEnvVar = "FABRIC8_BINARY_MIRROR"
+
+	kubernetesVersionFlag = "kubernetes-version"
+	kubectlVersionFlag    = "kubectl-version"
+	minishiftVersionFlag  = "minishift-version"
+	ocVersionFlag         = "oc-version"
+
+	downloadOnlyFlag = "download-only"
+
+	skipVerifyFlag = "skip-verify"
+
+	vmDriverFlag = "vm-driver"
+
+	outputFlag = "output"
+)
+
+// getCacheImages resolves the set of images to pre-pull when --cache-images
+// is set: the default fabric8 platform images plus anything added via
+// `fabric8 cache add` and persisted to ~/.fabric8/config.yaml
+func getCacheImages(o *outputFormatter) []string {
+	config, err := loadFabric8Config()
+	if err != nil {
+		o.warnf("Unable to load fabric8 config %v\n", err)
+	}
+
+	images := append([]string{}, defaultFabric8PlatformImages...)
+	for _, image := range config.Images {
+		if !containsString(images, image) {
+			images = append(images, image)
+		}
+	}
+	return images
 }
 
 // NewCmdInstall installs the dependencies to run the fabric8 microservices platform
@@ -78,97 +140,161 @@ func NewCmdInstall(f *cmdutil.Factory) *cobra.Command {
 
 		Run: func(cmd *cobra.Command, args []string) {
 			isMinishift := cmd.Flags().Lookup(minishiftFlag).Value.String() == "true"
-			install(isMinishift)
+			downloadOnly := cmd.Flags().Lookup(downloadOnlyFlag).Value.String() == "true"
+			skipVerify := cmd.Flags().Lookup(skipVerifyFlag).Value.String() == "true"
+			vmDriver := cmd.Flags().Lookup(vmDriverFlag).Value.String()
+			output := cmd.Flags().Lookup(outputFlag).Value.String()
+			if !isValidOutputFormat(output) {
+				util.Fatalf("Invalid --%s %q, must be one of text, json, yaml\n", outputFlag, output)
+			}
+			cacheImagesEnabled := cmd.Flags().Lookup(cacheImagesFlag).Value.String() == "true"
+			o := newOutputFormatter(output)
+			mirrors := getMirrorOptions(cmd, o)
+			versions := getVersionOptions(cmd)
+			install(isMinishift, downloadOnly, skipVerify, vmDriver, cacheImagesEnabled, mirrors, versions, o)
 		},
 	}
 	cmd.PersistentFlags().Bool(minishiftFlag, false, "Install minishift rather than minikube")
+	cmd.PersistentFlags().Bool(downloadOnlyFlag, false, "Only download the binaries into ~/"+binLocation+" without installing a local hypervisor/driver or touching your PATH")
+	cmd.PersistentFlags().String(binaryMirrorFlag, "", "Base URL of a mirror to use instead of the default upstream download locations for all binaries, also settable via the "+binaryMirrorEnvVar+" environment variable")
+	cmd.PersistentFlags().String(kubectlMirrorFlag, "", "Base URL of a mirror to use instead of the default upstream download location for kubectl, takes precedence over --"+binaryMirrorFlag)
+	cmd.PersistentFlags().String(ocMirrorFlag, "", "Base URL of a mirror to use instead of the default upstream download location for the oc client, takes precedence over --"+binaryMirrorFlag)
+	cmd.PersistentFlags().String(minikubeMirrorFlag, "", "Base URL of a mirror to use instead of the default upstream download location for minikube, takes precedence over --"+binaryMirrorFlag)
+	cmd.PersistentFlags().String(kubernetesVersionFlag, "", "The version of minikube to install rather than resolving the latest release from GitHub")
+	cmd.PersistentFlags().String(kubectlVersionFlag, "", "The version of kubectl to install rather than resolving the latest release from GitHub")
+	cmd.PersistentFlags().String(minishiftVersionFlag, "", "The version of minishift to install rather than resolving the latest release from GitHub")
+	cmd.PersistentFlags().String(ocVersionFlag, "", "The version of the oc client to install rather than using the default pinned release")
+	cmd.PersistentFlags().Bool(skipVerifyFlag, false, "Skip checksum and GPG signature verification of downloaded binaries (not recommended)")
+	cmd.PersistentFlags().String(vmDriverFlag, driverAuto, "The docker-machine driver to use: "+strings.Join([]string{driverVirtualBox, driverKVM2, driverLibvirt, driverXhyve, driverHyperkit, driverHyperV, driverAuto}, ", "))
+	cmd.PersistentFlags().String(outputFlag, outputText, "Output format for install progress: "+strings.Join([]string{outputText, outputJSON, outputYAML}, ", "))
+	cmd.PersistentFlags().Bool(cacheImagesFlag, false, "Pre-pull container images into the local cache (~/"+imageCacheDir+") for air-gapped bring-up, see the 'fabric8 cache' command to manage the image list")
 	return cmd
 }
 
-func install(isMinishift bool) {
-
-	writeFileLocation := getFabric8BinLocation()
-
-	err := os.MkdirAll(writeFileLocation, 0700)
-	if err != nil {
-		util.Errorf("Unable to create directory to download files %s %v\n", writeFileLocation, err)
+// getVersionOptions resolves the pinned versions to install from command
+// line flags; an empty version means resolve the latest release from GitHub,
+// except for oc where it means the default pinned release
+func getVersionOptions(cmd *cobra.Command) versionOptions {
+	return versionOptions{
+		kubernetes: cmd.Flags().Lookup(kubernetesVersionFlag).Value.String(),
+		kubectl:    cmd.Flags().Lookup(kubectlVersionFlag).Value.String(),
+		minishift:  cmd.Flags().Lookup(minishiftVersionFlag).Value.String(),
+		oc:         cmd.Flags().Lookup(ocVersionFlag).Value.String(),
 	}
+}
 
-	err = downloadDriver()
+// getMirrorOptions resolves the binary mirror URLs to use, preferring
+// command line flags, then the FABRIC8_BINARY_MIRROR environment variable
+// for the general mirror, then values persisted in ~/.fabric8/config.yaml
+func getMirrorOptions(cmd *cobra.Command, o *outputFormatter) mirrorOptions {
+	config, err := loadFabric8Config()
 	if err != nil {
-		util.Warnf("Unable to download driver %v\n", err)
+		o.warnf("Unable to load fabric8 config %v\n", err)
 	}
 
-	d := getDownloadProperties(isMinishift)
-	err = downloadKubernetes(d)
-	if err != nil {
-		util.Warnf("Unable to download kubernetes distro %v\n", err)
+	m := mirrorOptions{
+		general:  config.BinaryMirror,
+		kubectl:  config.KubectlMirror,
+		oc:       config.OcMirror,
+		minikube: config.MinikubeMirror,
 	}
 
-	err = downloadKubectlClient()
-	if err != nil {
-		util.Warnf("Unable to download client %v\n", err)
+	if envMirror := os.Getenv(binaryMirrorEnvVar); envMirror != "" {
+		m.general = envMirror
 	}
 
-	if d.isMiniShift {
-		err = downloadOpenShiftClient()
-		if err != nil {
-			util.Warnf("Unable to download client %v\n", err)
-		}
+	if v := cmd.Flags().Lookup(binaryMirrorFlag).Value.String(); v != "" {
+		m.general = v
+	}
+	if v := cmd.Flags().Lookup(kubectlMirrorFlag).Value.String(); v != "" {
+		m.kubectl = v
+	}
+	if v := cmd.Flags().Lookup(ocMirrorFlag).Value.String(); v != "" {
+		m.oc = v
+	}
+	if v := cmd.Flags().Lookup(minikubeMirrorFlag).Value.String(); v != "" {
+		m.minikube = v
 	}
 
+	return m
 }
-func downloadDriver() (err error) {
 
-	if runtime.GOOS == "darwin" {
-		util.Infof("fabric8 recommends OSX users use the xhyve driver\n")
-		info, err := exec.Command("brew", "info", "docker-machine-driver-xhyve").Output()
+// resolveMirror picks the base URL to download from: a per-binary override
+// if set, otherwise the general mirror if set, otherwise the given default
+func resolveMirror(specific, general, defaultURL string) string {
+	switch {
+	case specific != "":
+		return ensureTrailingSlash(specific)
+	case general != "":
+		return ensureTrailingSlash(general)
+	default:
+		return defaultURL
+	}
+}
 
-		if err != nil || strings.Contains(string(info), "Not installed") {
-			e := exec.Command("brew", "install", "docker-machine-driver-xhyve")
-			e.Stdout = os.Stdout
-			e.Stderr = os.Stderr
-			err = e.Run()
-			if err != nil {
-				return err
-			}
+func ensureTrailingSlash(url string) string {
+	if strings.HasSuffix(url, "/") {
+		return url
+	}
+	return url + "/"
+}
 
-			out, err := exec.Command("brew", "--prefix").Output()
-			if err != nil {
-				return err
-			}
+func install(isMinishift bool, downloadOnly bool, skipVerify bool, vmDriver string, cacheImagesEnabled bool, mirrors mirrorOptions, versions versionOptions, o *outputFormatter) {
+	writeFileLocation := getFabric8BinLocation()
 
-			brewPrefix := strings.TrimSpace(string(out))
+	err := os.MkdirAll(writeFileLocation, 0700)
+	if err != nil {
+		o.errorf("Unable to create directory to download files %s %v\n", writeFileLocation, err)
+	}
 
-			file := string(brewPrefix) + "/opt/docker-machine-driver-xhyve/bin/docker-machine-driver-xhyve"
-			e = exec.Command("sudo", "chown", "root:wheel", file)
-			e.Stdout = os.Stdout
-			e.Stderr = os.Stderr
-			err = e.Run()
-			if err != nil {
-				return err
-			}
+	if downloadOnly {
+		o.infof("Running in --%s mode, skipping hypervisor/driver install\n", downloadOnlyFlag)
+	} else {
+		err = o.step("driver", vmDriver, func() (stepResult, error) {
+			return downloadDriver(vmDriver, mirrors, skipVerify, o)
+		})
+		if err != nil {
+			o.warnf("Unable to download driver %v\n", err)
+		}
+	}
 
-			e = exec.Command("sudo", "chmod", "u+s", file)
-			e.Stdout = os.Stdout
-			e.Stderr = os.Stderr
-			err = e.Run()
-			if err != nil {
-				return err
-			}
+	d := getDownloadProperties(isMinishift, mirrors, versions)
+	d.skipVerify = skipVerify
+	err = o.step("kubernetes", d.kubeBinary, func() (stepResult, error) {
+		return downloadKubernetes(d, o)
+	})
+	if err != nil {
+		o.warnf("Unable to download kubernetes distro %v\n", err)
+	}
+
+	err = o.step("kubectl", kubectl, func() (stepResult, error) {
+		return downloadKubectlClient(mirrors, versions.kubectl, skipVerify, o)
+	})
+	if err != nil {
+		o.warnf("Unable to download client %v\n", err)
+	}
 
-			util.Success("xhyve driver installed\n")
-		} else {
-			util.Success("xhyve driver already installed\n")
+	if d.isMiniShift {
+		err = o.step("oc", oc, func() (stepResult, error) {
+			return downloadOpenShiftClient(mirrors, versions.oc, skipVerify, o)
+		})
+		if err != nil {
+			o.warnf("Unable to download client %v\n", err)
 		}
+	}
 
-	} else if runtime.GOOS == "linux" {
-		return errors.New("Driver install for " + runtime.GOOS + " not yet supported")
+	if cacheImagesEnabled {
+		images := getCacheImages(o)
+		err = o.step("cache-images", strings.Join(images, ","), func() (stepResult, error) {
+			return stepResult{}, cacheImages(images, o)
+		})
+		if err != nil {
+			o.warnf("Unable to cache images %v\n", err)
+		}
 	}
-	return nil
-}
 
-func downloadKubernetes(d downloadProperties) (err error) {
+}
+func downloadKubernetes(d downloadProperties, o *outputFormatter) (result stepResult, err error) {
 	os := runtime.GOOS
 	arch := runtime.GOARCH
 
@@ -178,68 +304,87 @@ func downloadKubernetes(d downloadProperties) (err error) {
 
 	_, err = exec.LookPath(d.kubeBinary)
 	if err != nil {
-		latestVersion, err := getLatestVersionFromGitHub(d.kubeDistroOrg, d.kubeDistroRepo)
+		version, err := resolveVersion(d.pinnedVersion, d.kubeDistroOrg, d.kubeDistroRepo, o)
 		if err != nil {
-			util.Errorf("Unable to get latest version for %s/%s %v", d.kubeDistroOrg, d.kubeDistroRepo, err)
-			return err
+			o.errorf("Unable to get latest version for %s/%s %v", d.kubeDistroOrg, d.kubeDistroRepo, err)
+			return result, err
 		}
+		result.version = version.String()
 
-		kubeURL := fmt.Sprintf(d.downloadURL+d.kubeDistroRepo+"/releases/"+d.extraPath+"v%s/%s-%s-%s", latestVersion, d.kubeDistroRepo, os, arch)
+		kubeURL := fmt.Sprintf(d.downloadURL+d.kubeDistroRepo+"/releases/"+d.extraPath+"v%s/%s-%s-%s", version, d.kubeDistroRepo, os, arch)
 		if runtime.GOOS == "windows" {
 			kubeURL += ".exe"
 		}
-		util.Infof("Downloading %s...\n", kubeURL)
+		result.url = kubeURL
+		o.infof("Downloading %s...\n", kubeURL)
 
 		writeFileLocation := getFabric8BinLocation()
 
-		err = downloadFile(writeFileLocation+d.kubeBinary, kubeURL)
+		dl, err := downloadFile(writeFileLocation+d.kubeBinary, kubeURL, d.skipVerify, o)
+		result.bytes = dl.bytes
+		result.sha256 = dl.sha256
 		if err != nil {
-			util.Errorf("Unable to download file %s/%s %v", writeFileLocation+d.kubeBinary, kubeURL, err)
-			return err
+			o.errorf("Unable to download file %s/%s %v", writeFileLocation+d.kubeBinary, kubeURL, err)
+			return result, err
 		}
-		util.Successf("Downloaded %s\n", d.kubeBinary)
+		o.successf("Downloaded %s\n", d.kubeBinary)
 	} else {
-		util.Successf("%s is already available on your PATH\n", d.kubeBinary)
+		o.successf("%s is already available on your PATH\n", d.kubeBinary)
 	}
 
-	return nil
+	return result, nil
+}
+
+// resolveVersion returns the pinned version if one was given, otherwise
+// resolves the latest release for the given org/repo from GitHub, using the
+// on-disk version cache
+func resolveVersion(pinnedVersion, githubOwner, githubRepo string, o *outputFormatter) (semver.Version, error) {
+	if pinnedVersion != "" {
+		return semver.Make(strings.TrimPrefix(pinnedVersion, "v"))
+	}
+	return getLatestVersionFromGitHubCached(githubOwner, githubRepo, o)
 }
 
-func downloadKubectlClient() (err error) {
+func downloadKubectlClient(mirrors mirrorOptions, pinnedVersion string, skipVerify bool, o *outputFormatter) (result stepResult, err error) {
 
 	os := runtime.GOOS
 	arch := runtime.GOARCH
 
 	_, err = exec.LookPath(kubectl)
 	if err != nil {
-		latestVersion, err := getLatestVersionFromGitHub(kubernetes, kubernetes)
+		version, err := resolveVersion(pinnedVersion, kubernetes, kubernetes, o)
 		if err != nil {
-			return fmt.Errorf("Unable to get latest version for %s/%s %v", kubernetes, kubernetes, err)
+			return result, fmt.Errorf("Unable to get latest version for %s/%s %v", kubernetes, kubernetes, err)
 		}
+		result.version = version.String()
 
-		clientURL := fmt.Sprintf("https://storage.googleapis.com/kubernetes-release/release/v%s/bin/%s/%s/%s", latestVersion, os, arch, kubectl)
+		kubectlBaseURL := resolveMirror(mirrors.kubectl, mirrors.general, kubeDownloadURL)
+		clientURL := fmt.Sprintf(kubectlBaseURL+"kubernetes-release/release/v%s/bin/%s/%s/%s", version, os, arch, kubectl)
 		if runtime.GOOS == "windows" {
 			clientURL += ".exe"
 		}
+		result.url = clientURL
 
-		util.Infof("Downloading %s...\n", clientURL)
+		o.infof("Downloading %s...\n", clientURL)
 
 		writeFileLocation := getFabric8BinLocation()
 
-		err = downloadFile(writeFileLocation+kubectl, clientURL)
+		dl, err := downloadFile(writeFileLocation+kubectl, clientURL, skipVerify, o)
+		result.bytes = dl.bytes
+		result.sha256 = dl.sha256
 		if err != nil {
-			util.Errorf("Unable to download file %s/%s %v", writeFileLocation+kubectl, clientURL, err)
-			return err
+			o.errorf("Unable to download file %s/%s %v", writeFileLocation+kubectl, clientURL, err)
+			return result, err
 		}
-		util.Successf("Downloaded %s\n", kubectl)
+		o.successf("Downloaded %s\n", kubectl)
 	} else {
-		util.Successf("%s is already available on your PATH\n", kubectl)
+		o.successf("%s is already available on your PATH\n", kubectl)
 	}
 
-	return nil
+	return result, nil
 }
 
-func downloadOpenShiftClient() (err error) {
+func downloadOpenShiftClient(mirrors mirrorOptions, pinnedVersion string, skipVerify bool, o *outputFormatter) (result stepResult, err error) {
 	os := runtime.GOOS
 	arch := runtime.GOARCH
 
@@ -248,9 +393,14 @@ func downloadOpenShiftClient() (err error) {
 
 		// need to fix the version we download as not able to work out the oc sha in the URL yet
 		sha := "565691c"
-		latestVersion := "1.2.2"
+		version := "1.2.2"
+		if pinnedVersion != "" {
+			version = strings.TrimPrefix(pinnedVersion, "v")
+		}
+		result.version = version
 
-		clientURL := fmt.Sprintf("https://github.com/openshift/origin/releases/download/v%s/openshift-origin-client-tools-v%s-%s", latestVersion, latestVersion, sha)
+		ocBaseURL := resolveMirror(mirrors.oc, mirrors.general, ocDownloadURL)
+		clientURL := fmt.Sprintf(ocBaseURL+"v%s/openshift-origin-client-tools-v%s-%s", version, version, sha)
 
 		switch runtime.GOOS {
 		case "windows":
@@ -260,75 +410,111 @@ func downloadOpenShiftClient() (err error) {
 		default:
 			clientURL += fmt.Sprintf(clientURL+"-%s-%s.tar.gz", os, arch)
 		}
+		result.url = clientURL
 
-		util.Infof("Downloading %s...\n", clientURL)
+		o.infof("Downloading %s...\n", clientURL)
 
 		writeFileLocation := getFabric8BinLocation()
 
-		err = downloadFile(writeFileLocation+oc+".zip", clientURL)
+		dl, err := downloadFile(writeFileLocation+oc+".zip", clientURL, skipVerify, o)
+		result.bytes = dl.bytes
+		result.sha256 = dl.sha256
 		if err != nil {
-			util.Errorf("Unable to download file %s/%s %v", writeFileLocation+oc, clientURL, err)
-			return err
+			o.errorf("Unable to download file %s/%s %v", writeFileLocation+oc, clientURL, err)
+			return result, err
 		}
 
 		switch runtime.GOOS {
 		case "windows":
 			err = unzip(writeFileLocation+oc+".zip", writeFileLocation+".")
 			if err != nil {
-				util.Errorf("Unable to unzip %s %v", writeFileLocation+oc+".zip", err)
-				return err
+				o.errorf("Unable to unzip %s %v", writeFileLocation+oc+".zip", err)
+				return result, err
 			}
 		case "darwin":
 			err = unzip(writeFileLocation+oc+".zip", writeFileLocation+".")
 			if err != nil {
-				util.Errorf("Unable to unzip %s %v", writeFileLocation+oc+".zip", err)
-				return err
+				o.errorf("Unable to unzip %s %v", writeFileLocation+oc+".zip", err)
+				return result, err
 			}
 		default:
 			err = unzip(writeFileLocation+oc+".tar.gz", writeFileLocation+".")
 			if err != nil {
-				util.Errorf("Unable to untar %s %v", writeFileLocation+oc+".tar.gz", err)
-				return err
+				o.errorf("Unable to untar %s %v", writeFileLocation+oc+".tar.gz", err)
+				return result, err
 			}
 		}
 
-		util.Successf("Downloaded %s\n", oc)
+		o.successf("Downloaded %s\n", oc)
 	} else {
-		util.Successf("%s is already available on your PATH\n", oc)
+		o.successf("%s is already available on your PATH\n", oc)
 	}
 
-	return nil
+	return result, nil
+}
+
+// downloadResult carries what downloadFile observed about the download, for
+// folding into a machine-readable step record
+type downloadResult struct {
+	bytes  int64
+	sha256 string
 }
 
 // download here until install and download binaries are supported in minishift
-func downloadFile(filepath string, url string) (err error) {
+func downloadFile(filepath string, url string, skipVerify bool, o *outputFormatter) (result downloadResult, err error) {
+
+	// download to a temp file first so a failed verification never leaves
+	// a bad binary in place
+	tmpFile := filepath + ".download"
 
-	// Create the file
-	out, err := os.Create(filepath)
+	out, err := os.Create(tmpFile)
 	if err != nil {
-		return err
+		return result, err
 	}
 	defer out.Close()
 
 	// Get the data
 	resp, err := http.Get(url)
 	if err != nil {
-		return err
+		return result, err
 	}
 	defer resp.Body.Close()
 
+	var body io.Reader = resp.Body
+	if o.format == outputText {
+		body = &progressReader{Reader: resp.Body, total: resp.ContentLength, onProgress: printDownloadProgress}
+	}
+
 	// Writer the body to file
-	_, err = io.Copy(out, resp.Body)
+	written, err := io.Copy(out, body)
 	if err != nil {
-		return err
+		return result, err
+	}
+	result.bytes = written
+	out.Close()
+	if o.format == outputText {
+		fmt.Println()
+	}
+
+	if skipVerify {
+		o.warnf("Skipping checksum/signature verification for %s (--%s)\n", url, skipVerifyFlag)
+		pinChecksum(tmpFile, o)
+	} else {
+		if err := verifyDownload(tmpFile, url, o); err != nil {
+			os.Remove(tmpFile)
+			return result, err
+		}
+	}
+
+	if sha, err := fileSHA256(tmpFile); err == nil {
+		result.sha256 = sha
 	}
 
 	// make it executable
-	os.Chmod(filepath, 0755)
-	if err != nil {
-		return err
+	if err := os.Chmod(tmpFile, 0755); err != nil {
+		return result, err
 	}
-	return nil
+	return result, os.Rename(tmpFile, filepath)
 }
 
 // borrowed from minishift until it supports install / download binaries
@@ -403,25 +589,27 @@ func isInstalled(isMinishift bool) bool {
 	return true
 }
 
-func getDownloadProperties(isMinishift bool) downloadProperties {
+func getDownloadProperties(isMinishift bool, mirrors mirrorOptions, versions versionOptions) downloadProperties {
 	d := downloadProperties{}
 
 	if isMinishift {
 		d.clientBinary = oc
 		d.extraPath = "download/"
 		d.kubeBinary = minishift
-		d.downloadURL = minishiftDownloadURL
+		d.downloadURL = resolveMirror("", mirrors.general, minishiftDownloadURL)
 		d.kubeDistroOrg = minishiftOwner
 		d.kubeDistroRepo = minishift
 		d.isMiniShift = true
+		d.pinnedVersion = versions.minishift
 
 	} else {
 		d.clientBinary = kubectl
 		d.kubeBinary = minikube
-		d.downloadURL = kubeDownloadURL
+		d.downloadURL = resolveMirror(mirrors.minikube, mirrors.general, kubeDownloadURL)
 		d.kubeDistroOrg = kubernetes
 		d.kubeDistroRepo = minikube
 		d.isMiniShift = false
+		d.pinnedVersion = versions.kubernetes
 	}
 	return d
 }
@@ -508,4 +696,4 @@ func ungzip(source, target string) error {
 		return err
 	}
 	return err
-}
\ No newline at end of file
+}
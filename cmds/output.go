@@ -0,0 +1,189 @@
+/**
+ * Copyright (C) 2015 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *         http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmds
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/fabric8io/gofabric8/util"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	outputText = "text"
+	outputJSON = "json"
+	outputYAML = "yaml"
+)
+
+// stepRecord is one entry of the machine-readable install output, emitted
+// once per completed step in --output=json/yaml mode
+type stepRecord struct {
+	Step       string `json:"step" yaml:"step"`
+	Binary     string `json:"binary" yaml:"binary"`
+	Version    string `json:"version,omitempty" yaml:"version,omitempty"`
+	URL        string `json:"url,omitempty" yaml:"url,omitempty"`
+	Bytes      int64  `json:"bytes,omitempty" yaml:"bytes,omitempty"`
+	SHA256     string `json:"sha256,omitempty" yaml:"sha256,omitempty"`
+	DurationMs int64  `json:"duration_ms" yaml:"duration_ms"`
+	Status     string `json:"status" yaml:"status"`
+	Error      string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// stepResult carries the metadata a download step collects about what it
+// did, so it can be folded into a stepRecord once the step completes
+type stepResult struct {
+	version string
+	url     string
+	bytes   int64
+	sha256  string
+}
+
+// outputFormatter gates the human readable util.Infof/Successf/Errorf/Warnf
+// logging to text mode, and emits one structured stepRecord per step in
+// json/yaml mode so CI and wrapper tools can consume install progress
+type outputFormatter struct {
+	format string
+}
+
+func newOutputFormatter(format string) *outputFormatter {
+	return &outputFormatter{format: format}
+}
+
+func isValidOutputFormat(format string) bool {
+	switch format {
+	case outputText, outputJSON, outputYAML:
+		return true
+	default:
+		return false
+	}
+}
+
+func (o *outputFormatter) infof(format string, args ...interface{}) {
+	if o.format == outputText {
+		util.Infof(format, args...)
+	}
+}
+
+func (o *outputFormatter) successf(format string, args ...interface{}) {
+	if o.format == outputText {
+		util.Successf(format, args...)
+	}
+}
+
+func (o *outputFormatter) warnf(format string, args ...interface{}) {
+	if o.format == outputText {
+		util.Warnf(format, args...)
+	}
+}
+
+func (o *outputFormatter) errorf(format string, args ...interface{}) {
+	if o.format == outputText {
+		util.Errorf(format, args...)
+	}
+}
+
+// step times fn, folds its stepResult and error into a stepRecord, and
+// emits the record in json/yaml mode
+func (o *outputFormatter) step(step, binary string, fn func() (stepResult, error)) error {
+	start := time.Now()
+	result, err := fn()
+
+	record := stepRecord{
+		Step:       step,
+		Binary:     binary,
+		Version:    result.version,
+		URL:        result.url,
+		Bytes:      result.bytes,
+		SHA256:     result.sha256,
+		DurationMs: time.Since(start).Nanoseconds() / int64(time.Millisecond),
+		Status:     "ok",
+	}
+	if err != nil {
+		record.Status = "error"
+		record.Error = err.Error()
+	}
+
+	o.emit(record)
+	return err
+}
+
+func (o *outputFormatter) emit(record stepRecord) {
+	switch o.format {
+	case outputJSON:
+		data, err := json.Marshal(record)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Unable to marshal install step as JSON: %v\n", err)
+			return
+		}
+		fmt.Println(string(data))
+	case outputYAML:
+		data, err := yaml.Marshal(record)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Unable to marshal install step as YAML: %v\n", err)
+			return
+		}
+		fmt.Print("---\n" + string(data))
+	}
+}
+
+// progressReader wraps an io.Reader, reporting bytes read against total
+// (typically the response's Content-Length) via onProgress
+type progressReader struct {
+	io.Reader
+	read       int64
+	total      int64
+	onProgress func(read, total int64)
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.read += int64(n)
+	if r.onProgress != nil {
+		r.onProgress(r.read, r.total)
+	}
+	return n, err
+}
+
+// printDownloadProgress renders a simple "\r"-updated progress bar; it is
+// only wired up in text output mode
+func printDownloadProgress(read, total int64) {
+	if total <= 0 {
+		fmt.Printf("\rDownloaded %d bytes", read)
+		return
+	}
+	percent := int(read * 100 / total)
+	fmt.Printf("\r[%-20s] %3d%% (%d/%d bytes)", progressBar(percent), percent, read, total)
+}
+
+func progressBar(percent int) string {
+	filled := percent * 20 / 100
+	if filled > 20 {
+		filled = 20
+	}
+	bar := make([]byte, 20)
+	for i := range bar {
+		if i < filled {
+			bar[i] = '='
+		} else {
+			bar[i] = ' '
+		}
+	}
+	return string(bar)
+}
@@ -0,0 +1,273 @@
+/**
+ * Copyright (C) 2015 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *         http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmds
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/crypto/openpgp"
+)
+
+// knownChecksums is a small bundled manifest of SHA256 checksums, keyed by
+// the downloaded file name, for releases we pin ourselves (e.g. the oc
+// client whose version is hardcoded in downloadOpenShiftClient). Anything not listed here falls
+// back to a checksum published alongside the release itself.
+//
+// This is empty for now: the pinned openshift-origin-client-tools release we
+// download predates upstream publishing a checksum file, so there's no
+// known-good value we can bundle and verify offline. Fabricating one here
+// would be worse than not having it - it would silently break every
+// default `oc` install the moment the byte-for-byte guess is wrong.
+// fetchExpectedChecksum failing falls back to verifyOrTrustOnFirstUse
+// instead, which refuses to pass an unverifiable download by default -
+// it must be accepted once via --skip-verify, which pins its checksum so
+// every install after that first one is genuinely verified. Add entries
+// here as releases with published checksums are pinned, to remove the
+// need for that first unverified install entirely.
+var knownChecksums = map[string]string{}
+
+// fabric8ReleaseKeyringArmored is the ASCII-armored fabric8 release public
+// key, used to verify detached GPG signatures on downloaded binaries when
+// upstream publishes one. No key is bundled yet: fabric8 does not currently
+// publish signed releases, so there is nothing real to embed. Leave this
+// empty rather than shipping a key that can't verify anything -
+// verifyGPGSignatureIfPublished treats an empty keyring as "can't verify,
+// don't fail the install".
+const fabric8ReleaseKeyringArmored = ``
+
+// verifyDownload checks the file at path against the checksum published for
+// url (falling back to the bundled manifest), and against a detached GPG
+// signature when one is published alongside the release
+func verifyDownload(path, url string, o *outputFormatter) error {
+	fileName := filepath.Base(path)
+
+	expectedSHA256, err := fetchExpectedChecksum(url, fileName, "sha256", sha256.New())
+	if err != nil {
+		if err := verifyOrTrustOnFirstUse(path, fileName, o); err != nil {
+			return err
+		}
+	} else {
+		if err := verifyChecksum(path, expectedSHA256, sha256.New()); err != nil {
+			return err
+		}
+		o.infof("Verified SHA256 checksum for %s\n", fileName)
+
+		if expectedSHA512, err := fetchExpectedChecksum(url, fileName, "sha512", sha512.New()); err == nil {
+			if err := verifyChecksum(path, expectedSHA512, sha512.New()); err != nil {
+				return err
+			}
+			o.infof("Verified SHA512 checksum for %s\n", fileName)
+		}
+	}
+
+	return verifyGPGSignatureIfPublished(path, url, o)
+}
+
+// fetchExpectedChecksum resolves the checksum we expect for fileName,
+// preferring the bundled manifest, then a sibling "<url>.<algo>" file, then
+// an aggregate "<algo 'SHA256SUMS' style>" manifest alongside the release
+func fetchExpectedChecksum(url, fileName, algo string, h hash.Hash) (string, error) {
+	if algo == "sha256" {
+		if sum, ok := knownChecksums[fileName]; ok {
+			return sum, nil
+		}
+	}
+
+	if sum, err := fetchSiblingChecksum(url + "." + algo); err == nil {
+		return sum, nil
+	}
+
+	sumsName := strings.ToUpper(algo) + "SUMS"
+	dir := url[:strings.LastIndex(url, "/")+1]
+	// the manifest lists the upstream file name from the URL, not the local
+	// name the download was saved under
+	return fetchChecksumFromManifest(dir+sumsName, url[len(dir):])
+}
+
+func fetchSiblingChecksum(url string) (string, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("no checksum published at %s (status %d)", url, resp.StatusCode)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	fields := strings.Fields(string(body))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("empty checksum file at %s", url)
+	}
+	return fields[0], nil
+}
+
+// fetchChecksumFromManifest fetches an aggregate "<hash>  <filename>" style
+// manifest (as published by upstream SHA256SUMS files) and returns the hash
+// for fileName
+func fetchChecksumFromManifest(url, fileName string) (string, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("no checksum manifest published at %s (status %d)", url, resp.StatusCode)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		if strings.TrimPrefix(fields[1], "*") == fileName {
+			return fields[0], nil
+		}
+	}
+	return "", fmt.Errorf("%s not listed in checksum manifest %s", fileName, url)
+}
+
+// fileSHA256 returns the hex-encoded SHA256 of the file at path, used to
+// report what was actually downloaded in machine-readable install output
+func fileSHA256(path string) (string, error) {
+	f, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	h.Write(f)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// verifyOrTrustOnFirstUse covers files with no published checksum to check
+// against (e.g. the pinned oc release, which predates upstream publishing
+// one). There is no real checksum to compare the first download against, so
+// it is not verified - that first download must be explicitly accepted via
+// --skip-verify, which also pins its SHA256 to the on-disk checksum cache.
+// Every later download of the same file name is then checked against that
+// pinned value, closing the MITM gap a missing checksum would otherwise
+// leave open on every install after the first
+func verifyOrTrustOnFirstUse(path, fileName string, o *outputFormatter) error {
+	actual, err := fileSHA256(path)
+	if err != nil {
+		return fmt.Errorf("unable to checksum %s: %v", fileName, err)
+	}
+
+	cache, err := loadChecksumCache()
+	if err != nil {
+		o.warnf("Unable to load checksum cache %v\n", err)
+		cache = checksumCache{}
+	}
+
+	if trusted, ok := cache[fileName]; ok {
+		if !strings.EqualFold(trusted, actual) {
+			return fmt.Errorf("checksum mismatch for %s: expected %s (trusted on a prior install), got %s", fileName, trusted, actual)
+		}
+		o.infof("Verified %s against the checksum trusted on a prior install\n", fileName)
+		return nil
+	}
+
+	return fmt.Errorf("no checksum is published for %s and none is pinned from a prior install; re-run with --%s to accept this download once and pin its checksum for future installs", fileName, skipVerifyFlag)
+}
+
+// pinChecksum records the SHA256 of path as trusted for its file name, so a
+// later verified install of the same file (one that didn't pass
+// --skip-verify) can be checked against it. Used when the caller has just
+// accepted an unverified download via --skip-verify; failures are logged and
+// otherwise ignored since this is a best-effort convenience, not the
+// verification step itself
+func pinChecksum(path string, o *outputFormatter) {
+	fileName := filepath.Base(path)
+
+	actual, err := fileSHA256(path)
+	if err != nil {
+		o.warnf("Unable to checksum %s to pin it for future installs %v\n", fileName, err)
+		return
+	}
+
+	cache, err := loadChecksumCache()
+	if err != nil {
+		cache = checksumCache{}
+	}
+	cache[fileName] = actual
+
+	if err := saveChecksumCache(cache); err != nil {
+		o.warnf("Unable to save checksum cache %v\n", err)
+	}
+}
+
+func verifyChecksum(path, expectedHex string, h hash.Hash) error {
+	f, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	h.Write(f)
+	actualHex := hex.EncodeToString(h.Sum(nil))
+	if !strings.EqualFold(actualHex, expectedHex) {
+		return fmt.Errorf("checksum mismatch: expected %s, got %s", expectedHex, actualHex)
+	}
+	return nil
+}
+
+// verifyGPGSignatureIfPublished verifies path against a detached signature
+// published as "<url>.asc", if one exists and we have a real fabric8 key to
+// check it against; a missing signature, or no bundled key, is not an error
+// since this is an optional extra check on top of the checksum
+func verifyGPGSignatureIfPublished(path, url string, o *outputFormatter) error {
+	if fabric8ReleaseKeyringArmored == "" {
+		return nil
+	}
+
+	resp, err := http.Get(url + ".asc")
+	if err != nil {
+		return nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil
+	}
+
+	keyring, err := openpgp.ReadArmoredKeyRing(strings.NewReader(fabric8ReleaseKeyringArmored))
+	if err != nil {
+		return fmt.Errorf("unable to read bundled fabric8 keyring: %v", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = openpgp.CheckArmoredDetachedSignature(keyring, file, resp.Body)
+	if err != nil {
+		return fmt.Errorf("GPG signature verification failed for %s: %v", path, err)
+	}
+	o.infof("Verified GPG signature for %s\n", filepath.Base(path))
+	return nil
+}
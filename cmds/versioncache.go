@@ -0,0 +1,112 @@
+/**
+ * Copyright (C) 2015 Red Hat, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *         http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmds
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/fabric8io/gofabric8/util"
+	"k8s.io/kubernetes/pkg/util/homedir"
+)
+
+const (
+	versionCacheFileName = "version-cache.json"
+
+	// versionCacheTTL is how long a cached "latest release" lookup is
+	// trusted for before we hit GitHub again, keeping us well under the
+	// unauthenticated rate limit when GH_TOKEN isn't set
+	versionCacheTTL = time.Hour
+)
+
+// versionCacheEntry is the cached result of a single GitHub "latest
+// release" lookup
+type versionCacheEntry struct {
+	Version   string    `json:"version"`
+	FetchedAt time.Time `json:"fetchedAt"`
+}
+
+// versionCache is keyed by "{org}/{repo}"
+type versionCache map[string]versionCacheEntry
+
+func getVersionCacheLocation() string {
+	home := homedir.HomeDir()
+	if home == "" {
+		util.Fatalf("No user home environment variable found for OS %s", runtime.GOOS)
+	}
+	return filepath.Join(home, ".fabric8", versionCacheFileName)
+}
+
+func loadVersionCache() (versionCache, error) {
+	c := versionCache{}
+	data, err := ioutil.ReadFile(getVersionCacheLocation())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c, nil
+		}
+		return c, err
+	}
+	err = json.Unmarshal(data, &c)
+	return c, err
+}
+
+func saveVersionCache(c versionCache) error {
+	path := getVersionCacheLocation()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0600)
+}
+
+// getLatestVersionFromGitHubCached wraps getLatestVersionFromGitHub with an
+// on-disk cache so repeated installs don't hit the GitHub rate limit,
+// particularly important since GH_TOKEN is optional
+func getLatestVersionFromGitHubCached(githubOwner, githubRepo string, o *outputFormatter) (semver.Version, error) {
+	key := githubOwner + "/" + githubRepo
+
+	cache, err := loadVersionCache()
+	if err != nil {
+		o.warnf("Unable to load version cache %v\n", err)
+		cache = versionCache{}
+	}
+
+	if entry, ok := cache[key]; ok && time.Since(entry.FetchedAt) < versionCacheTTL {
+		if v, err := semver.Make(entry.Version); err == nil {
+			return v, nil
+		}
+	}
+
+	v, err := getLatestVersionFromGitHub(githubOwner, githubRepo)
+	if err != nil {
+		return v, err
+	}
+
+	cache[key] = versionCacheEntry{Version: v.String(), FetchedAt: time.Now()}
+	if err := saveVersionCache(cache); err != nil {
+		o.warnf("Unable to save version cache %v\n", err)
+	}
+
+	return v, nil
+}